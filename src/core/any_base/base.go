@@ -1,55 +1,73 @@
 package any_base
 
 import (
+	"fmt"
+	"gitlab.com/aiku-open-source/go-help/src/core/set"
 	"math"
 	"sort"
 	"strconv"
 	"strings"
 )
 
-// 10进制数转换 n 进制
+// 10进制数转换 n 进制，num <= 0 或 num2char 少于 2 个字符时返回空字符串
 func DecimalToAny(num int, num2char []rune) string {
 	length := len(num2char)
+	if length < 2 {
+		return ""
+	}
 	var str []rune
-	for {
-		if num <= 0 {
-			break
-		}
+	for num > 0 {
 		quotient := num % length // 余数
-		str = append([]rune{num2char[quotient]}, str...)
+		str = append(str, num2char[quotient])
 		num = num / length // 商数
 	}
-	return string(str)
+	return string(Reverse(str))
 }
 
-// n 进制数转换 10 进制
+// n 进制数转换 10 进制，str 中有不属于 num2char 的字符或结果溢出 int 时返回 -1
+// 需要具体错误时使用 AnyToDecimalStrict
 func AnyToDecimal(str string, num2char []rune) int {
-	//    $len = strlen($bas);
-	//    $str = strrev($str);
-	//    $num = 0;
-	//    for ($i = 0; $i < strlen($str); $i++) {
-	//        $pos = strpos($bas, $str[$i]);
-	//        $num = $num + (pow($len, $i) * $pos);
-	//    }
-	length := float64(len(num2char))
-
-	r := Reverse([]rune(str))
-	max := len(r)
+	num, err := AnyToDecimalStrict(str, num2char)
+	if err != nil {
+		return -1
+	}
+	return num
+}
 
-	var num float64
-	for i := 0; i < max; i++ {
-		num = num + (math.Pow(length, float64(i)) * float64(find(num2char, r[i])))
+// AnyToDecimalStrict 同 AnyToDecimal，str 中有不属于 num2char 的字符或结果溢出 int 时返回错误
+func AnyToDecimalStrict(str string, num2char []rune) (int, error) {
+	index := make(map[rune]int, len(num2char))
+	for i, c := range num2char {
+		if _, ok := index[c]; !ok {
+			index[c] = i
+		}
 	}
-	return int(num)
+	length := len(num2char)
+	var num int
+	for _, c := range str {
+		pos, ok := index[c]
+		if !ok {
+			return 0, fmt.Errorf("character %q not in alphabet", c)
+		}
+		// 按整数逐位累加，避免浮点数精度丢失
+		if num > (math.MaxInt-pos)/length {
+			return 0, fmt.Errorf("%q overflows int", str)
+		}
+		num = num*length + pos
+	}
+	return num, nil
 }
 
-func find(num2char []rune, str rune) int {
-	for i, s := range num2char {
-		if s == str {
-			return i
+// AnyToDecimalDetect 依次用 alphabets 中的每个字符集尝试解码，返回第一个能完整解码 str 的结果和对应的字符集
+// 多个字符集都能解码时（例如 base58 是 base62 的子集）以传入顺序为准
+func AnyToDecimalDetect(str string, alphabets ...[]rune) (int, []rune, error) {
+	for _, alphabet := range alphabets {
+		num, err := AnyToDecimalStrict(str, alphabet)
+		if err == nil {
+			return num, alphabet, nil
 		}
 	}
-	return -1
+	return 0, nil, fmt.Errorf("no alphabet matches %q", str)
 }
 
 func Reverse(r []rune) []rune {
@@ -61,28 +79,19 @@ func Reverse(r []rune) []rune {
 }
 
 func GetMap() (m map[int]struct{}) {
-	m = map[int]struct{}{
-		38: {},
-		60: {},
-		62: {},
-		34: {},
-		92: {},
-	}
+	s := set.New(38, 60, 62, 34, 92)
 	for i := 126; i < 161; i++ {
-		m[i] = struct{}{}
+		s.Add(i)
 	}
-	return
+	return s
 }
 
 func GetTenToAny(m map[int]struct{}) (tenToAny []rune) {
-	var ok bool
-	//var n int
+	skip := set.Set[int](m)
 	for i := 33; i < 256; i++ {
-		if _, ok = m[i]; ok {
+		if skip.Contains(i) {
 			continue
 		}
-		//tenToAny[n] = string(rune(i))
-		//n++
 		tenToAny = append(tenToAny, rune(i))
 	}
 	return
@@ -94,11 +103,14 @@ func IntegerGroupingEncode(list []int64, sep string) (res string) {
 	}
 	var s string
 	var prev int64
-	sort.Slice(list, func(i, j int) bool {
-		return list[i] < list[j]
+	// 复制一份再排序，不修改调用方的切片
+	sorted := make([]int64, len(list))
+	copy(sorted, list)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
 	})
-	var r []string
-	for _, v := range list {
+	r := make([]string, 0, len(sorted))
+	for _, v := range sorted {
 		s = strconv.FormatInt(v-prev, 10)
 		r = append(r, s)
 		prev = v
@@ -123,3 +135,45 @@ func IntegerGroupingDecode(input string, sep string) (res []int64) {
 	}
 	return
 }
+
+// IntegerGroupingDecodeStrict 同 IntegerGroupingDecode，但解析失败或解码结果不是非递减时返回错误
+func IntegerGroupingDecodeStrict(input string, sep string) (res []int64, err error) {
+	if len(input) == 0 {
+		return
+	}
+	list := strings.Split(input, sep)
+	res = make([]int64, 0, len(list))
+	var prev int64
+	for i, v := range list {
+		delta, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("integer grouping decode index %d: %w", i, err)
+		}
+		// 第一个值是原始值，可以为负，之后都是与前一个值的差
+		if i > 0 && delta < 0 {
+			return nil, fmt.Errorf("integer grouping decode index %d: negative delta %d", i, delta)
+		}
+		prev += delta
+		res = append(res, prev)
+	}
+	return
+}
+
+// MaxValue 返回 alphabetLen 进制下 width 位能表示的最大值，即 alphabetLen^width - 1
+// 结果超出 int64 时返回错误，不会溢出回绕
+func MaxValue(alphabetLen, width int) (int64, error) {
+	if alphabetLen < 1 || width < 0 {
+		return 0, fmt.Errorf("invalid alphabet length %d or width %d", alphabetLen, width)
+	}
+	// 用 uint64 计算 alphabetLen^width，允许的最大值是 2^63（减一后正好是 math.MaxInt64）
+	const limit = uint64(math.MaxInt64) + 1
+	base := uint64(alphabetLen)
+	res := uint64(1)
+	for i := 0; i < width; i++ {
+		if res > limit/base {
+			return 0, fmt.Errorf("%d^%d overflows int64", alphabetLen, width)
+		}
+		res *= base
+	}
+	return int64(res - 1), nil
+}
@@ -5,6 +5,8 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"fmt"
+	"math"
+	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
@@ -110,3 +112,150 @@ func TestB(t *testing.T) {
 	fmt.Println("[tenToAny] ", tenToAny)
 	fmt.Println("[解密结果] ", AnyToDecimal(e, tenToAny))
 }
+
+func TestIntegerGroupingDecodeStrict(t *testing.T) {
+	list := []int64{-5, 3, 3, 10, 1000}
+	res, err := IntegerGroupingDecodeStrict(IntegerGroupingEncode(list, ","), ",")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(res) != fmt.Sprint(list) {
+		t.Fatalf("decode = %v, want %v", res, list)
+	}
+	for _, input := range []string{"1,2,x", "1,,2", "5,-1", "1 2"} {
+		if res, err := IntegerGroupingDecodeStrict(input, ","); err == nil {
+			t.Errorf("decode %q = %v, want error", input, res)
+		}
+	}
+	if res, err := IntegerGroupingDecodeStrict("", ","); err != nil || res != nil {
+		t.Errorf("decode empty = %v, %v", res, err)
+	}
+}
+
+func TestIntegerGroupingEncodeKeepsInput(t *testing.T) {
+	list := []int64{30, 10, 20}
+	ig := IntegerGroupingEncode(list, " ")
+	if ig != "10 10 10" {
+		t.Fatalf("encode = %q, want %q", ig, "10 10 10")
+	}
+	if fmt.Sprint(list) != "[30 10 20]" {
+		t.Fatalf("input reordered to %v", list)
+	}
+}
+
+func TestMaxValue(t *testing.T) {
+	cases := []struct {
+		alphabetLen, width int
+		want               int64
+		wantErr            bool
+	}{
+		{10, 3, 999, false},
+		{62, 0, 0, false},
+		{1, 5, 0, false},
+		{62, 10, 839299365868340223, false},
+		{2, 63, math.MaxInt64, false},
+		{2, 64, 0, true},
+		{62, 11, 0, true},
+		{0, 3, 0, true},
+		{10, -1, 0, true},
+	}
+	for _, c := range cases {
+		got, err := MaxValue(c.alphabetLen, c.width)
+		if (err != nil) != c.wantErr || got != c.want {
+			t.Errorf("MaxValue(%d, %d) = %d, %v; want %d, err=%v", c.alphabetLen, c.width, got, err, c.want, c.wantErr)
+		}
+	}
+}
+
+func TestAnyToDecimalDetect(t *testing.T) {
+	base58 := []rune("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
+	base62 := []rune("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+
+	num, alphabet, err := AnyToDecimalDetect(DecimalToAny(123456789, base58), base58, base62)
+	if err != nil || num != 123456789 || string(alphabet) != string(base58) {
+		t.Fatalf("detect base58 = %d, %v", num, err)
+	}
+	// 含有 0 的只能是 base62
+	code := DecimalToAny(62*62*5, base62)
+	num, alphabet, err = AnyToDecimalDetect(code, base58, base62)
+	if err != nil || num != 62*62*5 || string(alphabet) != string(base62) {
+		t.Fatalf("detect base62 %q = %d, %v", code, num, err)
+	}
+	if _, _, err = AnyToDecimalDetect("abc-", base58, base62); err == nil {
+		t.Fatal("detect invalid character should fail")
+	}
+}
+
+func TestUUID(t *testing.T) {
+	base62 := []rune("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+	r := rand.New(rand.NewSource(1))
+	uuids := [][16]byte{{}, {15: 1}}
+	var max [16]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+	uuids = append(uuids, max)
+	for i := 0; i < 100; i++ {
+		var u [16]byte
+		r.Read(u[:])
+		uuids = append(uuids, u)
+	}
+	for _, u := range uuids {
+		code := EncodeUUID(u, base62)
+		if len(code) > 22 {
+			t.Fatalf("EncodeUUID(%x) = %q, longer than 22", u, code)
+		}
+		got, err := DecodeUUID(code, base62)
+		if err != nil || got != u {
+			t.Fatalf("DecodeUUID(%q) = %x, %v; want %x", code, got, err, u)
+		}
+	}
+	if _, err := DecodeUUID("zzzzzzzzzzzzzzzzzzzzzz", base62); err == nil {
+		t.Fatal("decode value over 128 bits should fail")
+	}
+	if _, err := DecodeUUID("abc-", base62); err == nil {
+		t.Fatal("decode invalid character should fail")
+	}
+}
+
+func FuzzDecimalToAny(f *testing.F) {
+	tenToAny := GetTenToAny(GetMap())
+	for _, n := range []int{0, 1, 32123456789, math.MaxInt64} {
+		f.Add(n)
+	}
+	f.Fuzz(func(t *testing.T, n int) {
+		if n < 0 {
+			return
+		}
+		e := DecimalToAny(n, tenToAny)
+		got, err := AnyToDecimalStrict(e, tenToAny)
+		if err != nil || got != n {
+			t.Fatalf("round trip %d -> %q -> %d, %v", n, e, got, err)
+		}
+	})
+}
+
+func FuzzAnyToDecimal(f *testing.F) {
+	tenToAny := GetTenToAny(GetMap())
+	for _, s := range []string{"", "!", "abc", "a b", "~", "ÿÿÿÿÿÿÿÿÿÿ", "\xff"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		lenient := AnyToDecimal(s, tenToAny)
+		num, err := AnyToDecimalStrict(s, tenToAny)
+		if err != nil {
+			if lenient != -1 {
+				t.Fatalf("AnyToDecimal(%q) = %d, want -1 for %v", s, lenient, err)
+			}
+			return
+		}
+		if lenient != num || num < 0 {
+			t.Fatalf("AnyToDecimal(%q) = %d, strict = %d", s, lenient, num)
+		}
+		// 去掉前导的 0 值字符后应能编码回原字符串
+		trimmed := strings.TrimLeft(s, string(tenToAny[0]))
+		if e := DecimalToAny(num, tenToAny); e != trimmed {
+			t.Fatalf("DecimalToAny(%d) = %q, want %q", num, e, trimmed)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package any_base
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// EncodeUUID 把 16 字节（按大端序视为 128 位整数）转换为 alphabet 进制的字符串，alphabet 至少需要 2 个字符
+func EncodeUUID(u [16]byte, alphabet []rune) string {
+	if len(alphabet) < 2 {
+		return ""
+	}
+	num := new(big.Int).SetBytes(u[:])
+	if num.Sign() == 0 {
+		return string(alphabet[0])
+	}
+	base := big.NewInt(int64(len(alphabet)))
+	mod := new(big.Int)
+	var str []rune
+	for num.Sign() > 0 {
+		num.DivMod(num, base, mod)
+		str = append(str, alphabet[mod.Int64()])
+	}
+	return string(Reverse(str))
+}
+
+// DecodeUUID 是 EncodeUUID 的逆运算，字符不在 alphabet 中或结果超出 128 位时返回错误
+func DecodeUUID(str string, alphabet []rune) (u [16]byte, err error) {
+	if len(alphabet) < 2 {
+		return u, fmt.Errorf("alphabet length %d less than 2", len(alphabet))
+	}
+	index := make(map[rune]int64, len(alphabet))
+	for i, c := range alphabet {
+		if _, ok := index[c]; !ok {
+			index[c] = int64(i)
+		}
+	}
+	base := big.NewInt(int64(len(alphabet)))
+	num := new(big.Int)
+	for _, c := range str {
+		pos, ok := index[c]
+		if !ok {
+			return u, fmt.Errorf("character %q not in alphabet", c)
+		}
+		num.Mul(num, base).Add(num, big.NewInt(pos))
+	}
+	if num.BitLen() > 128 {
+		return u, fmt.Errorf("%q overflows 128 bits", str)
+	}
+	num.FillBytes(u[:])
+	return u, nil
+}
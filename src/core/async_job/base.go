@@ -2,16 +2,16 @@ package async_job
 
 import (
 	"context"
-	"gitlab.com/aiku-open-source/go-help/src/core/hotfix"
+	"sort"
 	"sync"
 )
 
 type (
 	Job func(ctx context.Context, req interface{}, resp interface{}, err error)
 
-	jobList struct {
-		sync.Mutex
-		jobs []Job
+	priorityJob struct {
+		priority int
+		job      Job
 	}
 )
 
@@ -19,41 +19,44 @@ var (
 	instanceSM = sync.Map{}
 )
 
-func delInstance(ctx context.Context) {
-	instanceSM.Delete(ctx)
+func Push(ctx context.Context, key string, f Job) {
+	PushWithPriority(ctx, key, 0, f)
 }
 
-func getInstance(key string) (result *jobList) {
-	var (
-		ok bool
-		v  interface{}
-	)
-	v, ok = instanceSM.Load(key)
+// PushWithPriority 注册带优先级的任务，Run 时 priority 大的先执行，Push 注册的任务优先级为 0
+// 同优先级按注册顺序执行（稳定排序）
+func PushWithPriority(_ context.Context, key string, priority int, f Job) {
+	pushItem(&instanceSM, key, priorityJob{priority: priority, job: f})
+}
+
+// Run 执行 key 下注册的所有任务，执行前即从注册表中删除，执行期间再 Push 的任务留给下一次 Run
+// 单个任务 panic 只记录日志，不影响后面的任务
+func Run(ctx context.Context, key string, req interface{}, resp interface{}, err error) {
+	jobs, ok := takeItems[priorityJob](&instanceSM, key)
 	if !ok {
-		result = &jobList{
-			jobs: []Job{},
-		}
-		instanceSM.Store(key, result)
-	} else {
-		result = v.(*jobList)
+		return
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].priority > jobs[j].priority
+	})
+	for _, j := range jobs {
+		runSafe(func() { j.job(ctx, req, resp, err) })
 	}
-
 	return
 }
 
-func Push(_ context.Context, key string, f Job) {
-	result := getInstance(key)
-	result.Lock()
-	result.jobs = append(result.jobs, f)
-	result.Unlock()
+// Count 返回 key 下已注册、尚未 Run 的任务数
+func Count(key string) int {
+	return countItems[priorityJob](&instanceSM, key)
 }
 
-func Run(ctx context.Context, key string, req interface{}, resp interface{}, err error) {
-	defer hotfix.RecoverError()
-	defer delInstance(ctx)
-	result := getInstance(key)
-	for _, job := range result.jobs {
-		job(ctx, req, resp, err)
-	}
-	return
+// Keys 返回所有已注册、尚未 Run 的 key，按字典序排列
+func Keys() []string {
+	var keys []string
+	instanceSM.Range(func(k, _ interface{}) bool {
+		keys = append(keys, k.(string))
+		return true
+	})
+	sort.Strings(keys)
+	return keys
 }
@@ -0,0 +1,65 @@
+package async_job
+
+import (
+	"gitlab.com/aiku-open-source/go-help/src/core/hotfix"
+	"sync"
+)
+
+// takeList 是包级 Push/Run 与 TypedRegistry 共用的任务列表
+type takeList[T any] struct {
+	sync.Mutex
+	items []T
+	taken bool // 已被 Run 取走，之后的 Push 需要重新获取列表
+}
+
+// pushItem 把 item 追加到 sm 中 key 对应的列表，列表不存在时创建
+func pushItem[T any](sm *sync.Map, key string, item T) {
+	for {
+		// 列表通常已存在，先 Load，避免每次 Push 都分配一个用不上的新列表
+		v, ok := sm.Load(key)
+		if !ok {
+			v, _ = sm.LoadOrStore(key, &takeList[T]{})
+		}
+		list := v.(*takeList[T])
+		list.Lock()
+		// 拿到列表后被 Run 取走了，追加进去也不会再执行，重新获取
+		if list.taken {
+			list.Unlock()
+			continue
+		}
+		list.items = append(list.items, item)
+		list.Unlock()
+		return
+	}
+}
+
+// takeItems 从 sm 中删除 key 对应的列表并返回其中的元素，之后的 pushItem 会创建新列表
+func takeItems[T any](sm *sync.Map, key string) ([]T, bool) {
+	v, ok := sm.LoadAndDelete(key)
+	if !ok {
+		return nil, false
+	}
+	list := v.(*takeList[T])
+	list.Lock()
+	defer list.Unlock()
+	list.taken = true
+	return list.items, true
+}
+
+// countItems 返回 sm 中 key 对应列表的元素个数
+func countItems[T any](sm *sync.Map, key string) int {
+	v, ok := sm.Load(key)
+	if !ok {
+		return 0
+	}
+	list := v.(*takeList[T])
+	list.Lock()
+	defer list.Unlock()
+	return len(list.items)
+}
+
+// runSafe 执行 f，panic 只记录日志，不影响调用方继续执行后面的任务
+func runSafe(f func()) {
+	defer hotfix.RecoverError()
+	f()
+}
@@ -0,0 +1,31 @@
+package async_job
+
+import (
+	"context"
+	"sync"
+)
+
+type (
+	TypedJob[Req, Resp any] func(ctx context.Context, req Req, resp Resp, err error)
+
+	// TypedRegistry 与包级 Push/Run 相同，但 req/resp 是确定的类型，不需要类型断言
+	// 零值可用，与包级函数和其他 TypedRegistry 互不影响
+	TypedRegistry[Req, Resp any] struct {
+		sm sync.Map
+	}
+)
+
+func (r *TypedRegistry[Req, Resp]) Push(_ context.Context, key string, f TypedJob[Req, Resp]) {
+	pushItem(&r.sm, key, f)
+}
+
+// Run 按注册顺序执行 key 下的所有任务，执行后清除，单个任务 panic 不影响后面的任务
+func (r *TypedRegistry[Req, Resp]) Run(ctx context.Context, key string, req Req, resp Resp, err error) {
+	jobs, ok := takeItems[TypedJob[Req, Resp]](&r.sm, key)
+	if !ok {
+		return
+	}
+	for _, job := range jobs {
+		runSafe(func() { job(ctx, req, resp, err) })
+	}
+}
@@ -3,9 +3,9 @@ package async_job
 import (
 	"context"
 	"fmt"
-	"math/rand"
+	"sync"
+	"sync/atomic"
 	"testing"
-	"time"
 )
 
 func init() {
@@ -13,34 +13,210 @@ func init() {
 }
 
 func TestDo(t *testing.T) {
-	var err error
-	rand.Seed(time.Now().Unix())
-	if rand.Int()%2 == 0 {
-		err = fmt.Errorf("失败")
+	// 成功和失败两种情况都跑一遍，不依赖随机数
+	for _, err := range []error{nil, fmt.Errorf("失败")} {
+		fmt.Println("当前请求错误", err)
+		ctx := context.Background()
+		key1 := fmt.Sprintf("skip_on_error_%v", err != nil)
+		var ran1 int
+		for i := 0; i < 10; i++ {
+			func(iii int) {
+				Push(ctx, key1, func(ctx context.Context, req interface{}, resp interface{}, err error) {
+					if err != nil {
+						return
+					}
+					ran1++
+					fmt.Printf("执行第%d个错误时不执行的函数\n", iii+1)
+				})
+			}(i)
+		}
+		Run(ctx, key1, 1, 1, err)
+
+		ctx2 := context.Background()
+		key2 := fmt.Sprintf("always_%v", err != nil)
+		var ran2 int
+		for i := 0; i < 10; i++ {
+			func(iii int) {
+				Push(ctx2, key2, func(ctx context.Context, req interface{}, resp interface{}, err error) {
+					ran2++
+					fmt.Printf("执行第%d个错误时也执行的函数\n", iii+1)
+				})
+			}(i)
+		}
+		Run(ctx2, key2, 2, 2, err)
+
+		want1 := 10
+		if err != nil {
+			want1 = 0
+		}
+		if ran1 != want1 || ran2 != 10 {
+			t.Errorf("err=%v: ran %d/%d jobs, want %d/10", err, ran1, ran2, want1)
+		}
+	}
+}
+
+type testReq struct {
+	ID int
+}
+
+func TestTypedRegistry(t *testing.T) {
+	var registry TypedRegistry[*testReq, string]
+	ctx := context.Background()
+	var got []string
+	for i := 0; i < 3; i++ {
+		registry.Push(ctx, "typed", func(ctx context.Context, req *testReq, resp string, err error) {
+			got = append(got, fmt.Sprintf("%d:%s", req.ID, resp))
+		})
+	}
+	registry.Run(ctx, "typed", &testReq{ID: 7}, "ok", nil)
+	if fmt.Sprint(got) != "[7:ok 7:ok 7:ok]" {
+		t.Fatalf("ran %v", got)
+	}
+	// 执行后清除，再次 Run 不会重复执行
+	registry.Run(ctx, "typed", &testReq{ID: 8}, "again", nil)
+	if len(got) != 3 {
+		t.Fatalf("jobs ran again after Run: %v", got)
+	}
+}
+
+func TestPushWithPriority(t *testing.T) {
+	ctx := context.Background()
+	key := "priority"
+	var got []string
+	push := func(name string, priority int) {
+		PushWithPriority(ctx, key, priority, func(ctx context.Context, req interface{}, resp interface{}, err error) {
+			got = append(got, name)
+		})
+	}
+	push("cleanup", -10)
+	Push(ctx, key, func(ctx context.Context, req interface{}, resp interface{}, err error) {
+		got = append(got, "default")
+	})
+	push("notify1", 10)
+	push("notify2", 10)
+	push("cleanup2", -10)
+	Run(ctx, key, nil, nil, nil)
+	if fmt.Sprint(got) != "[notify1 notify2 default cleanup cleanup2]" {
+		t.Fatalf("run order %v", got)
+	}
+}
+
+func TestCountKeys(t *testing.T) {
+	ctx := context.Background()
+	job := func(ctx context.Context, req interface{}, resp interface{}, err error) {}
+	Push(ctx, "count_b", job)
+	Push(ctx, "count_a", job)
+	Push(ctx, "count_a", job)
+	if Count("count_a") != 2 || Count("count_b") != 1 || Count("count_none") != 0 {
+		t.Fatalf("Count = %d, %d", Count("count_a"), Count("count_b"))
+	}
+	if keys := fmt.Sprint(Keys()); keys != "[count_a count_b]" {
+		t.Fatalf("Keys = %s", keys)
 	}
-	fmt.Println("当前请求错误", err)
+	// Run 之后按 key 清除，不再残留
+	Run(ctx, "count_a", nil, nil, nil)
+	Run(ctx, "count_b", nil, nil, nil)
+	if Count("count_a") != 0 || len(Keys()) != 0 {
+		t.Fatalf("after Run Count = %d, Keys = %v", Count("count_a"), Keys())
+	}
+}
+
+func TestPushRunConcurrent(t *testing.T) {
+	var registry TypedRegistry[int, int]
 	ctx := context.Background()
-	i1 := 1
-	for i := 0; i < 10; i++ {
-		func(iii int) {
-			Push(ctx, func(ctx context.Context, req interface{}, resp interface{}, err error) {
-				if err != nil {
-					return
+	for _, c := range []struct {
+		name string
+		push func(job func())
+		run  func()
+	}{
+		{
+			name: "package",
+			push: func(job func()) {
+				Push(ctx, "concurrent", func(ctx context.Context, req interface{}, resp interface{}, err error) { job() })
+			},
+			run: func() { Run(ctx, "concurrent", nil, nil, nil) },
+		},
+		{
+			name: "typed",
+			push: func(job func()) {
+				registry.Push(ctx, "concurrent", func(ctx context.Context, req int, resp int, err error) { job() })
+			},
+			run: func() { registry.Run(ctx, "concurrent", 0, 0, nil) },
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			const pushers, perPusher = 8, 500
+			var ran int64
+			job := func() { atomic.AddInt64(&ran, 1) }
+			var wg sync.WaitGroup
+			for i := 0; i < pushers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < perPusher; j++ {
+						c.push(job)
+					}
+				}()
+			}
+			stop := make(chan struct{})
+			runDone := make(chan struct{})
+			go func() {
+				defer close(runDone)
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						c.run()
+					}
 				}
-				fmt.Printf("执行第%d个错误时不执行的函数\n", iii+1)
-			})
-		}(i)
-	}
-	Run(ctx, i1, i1, err)
-	ctx2 := context.Background()
-	i2 := 1
-	for i := 0; i < 10; i++ {
-		func(iii int) {
-			Push(ctx, func(ctx context.Context, req interface{}, resp interface{}, err error) {
-				fmt.Printf("执行第%d个错误时也执行的函数\n", iii+1)
-			})
-		}(i)
-	}
-	Run(ctx2, i2, i2, err)
-	time.Sleep(1 * time.Second)
+			}()
+			wg.Wait()
+			close(stop)
+			<-runDone
+			// 所有 Push 的任务最终都要执行，不能落在已被取走的列表上
+			c.run()
+			if ran != pushers*perPusher {
+				t.Fatalf("ran %d jobs, want %d", ran, pushers*perPusher)
+			}
+		})
+	}
+}
+
+func TestRunPanic(t *testing.T) {
+	ctx := context.Background()
+	var ran []int
+	Push(ctx, "panic", func(ctx context.Context, req interface{}, resp interface{}, err error) {
+		ran = append(ran, 1)
+	})
+	Push(ctx, "panic", func(ctx context.Context, req interface{}, resp interface{}, err error) {
+		panic("job panic")
+	})
+	Push(ctx, "panic", func(ctx context.Context, req interface{}, resp interface{}, err error) {
+		ran = append(ran, 3)
+	})
+	Run(ctx, "panic", nil, nil, nil)
+	// panic 的任务后面的任务照常执行
+	if fmt.Sprint(ran) != "[1 3]" {
+		t.Fatalf("ran = %v, want [1 3]", ran)
+	}
+}
+
+func TestTypedRegistryRunPanic(t *testing.T) {
+	var registry TypedRegistry[int, int]
+	ctx := context.Background()
+	var ran []int
+	registry.Push(ctx, "panic", func(ctx context.Context, req int, resp int, err error) {
+		ran = append(ran, 1)
+	})
+	registry.Push(ctx, "panic", func(ctx context.Context, req int, resp int, err error) {
+		panic("job panic")
+	})
+	registry.Push(ctx, "panic", func(ctx context.Context, req int, resp int, err error) {
+		ran = append(ran, 3)
+	})
+	registry.Run(ctx, "panic", 0, 0, nil)
+	if fmt.Sprint(ran) != "[1 3]" {
+		t.Fatalf("ran = %v, want [1 3]", ran)
+	}
 }
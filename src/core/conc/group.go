@@ -0,0 +1,73 @@
+package conc
+
+import (
+	"context"
+	"gitlab.com/aiku-open-source/go-help/src/core/hotfix"
+	"sync"
+)
+
+// Group 类似 errgroup.Group，每个协程都带 panic 恢复，panic 会作为 error 返回
+// 零值可用；通过 WithContext 创建时第一个错误会取消返回的 ctx
+type Group struct {
+	cancel func()
+
+	wg  sync.WaitGroup
+	sem chan struct{}
+
+	errOnce sync.Once
+	err     error
+}
+
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit 限制同时运行的协程数，n < 0 表示不限制，需要在调用 Go 之前设置
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go 在新协程中执行 f，达到并发上限时阻塞直到有协程结束
+func (g *Group) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		if err := g.run(f); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				if g.cancel != nil {
+					g.cancel()
+				}
+			})
+		}
+	}()
+}
+
+// Wait 等待所有协程结束，返回第一个错误
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	return g.err
+}
+
+func (g *Group) run(f func() error) (err error) {
+	defer hotfix.RecoverAsError(&err)
+	return f()
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
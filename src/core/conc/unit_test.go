@@ -0,0 +1,86 @@
+package conc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func init() {
+
+}
+
+func TestGroup(t *testing.T) {
+	var g Group
+	var n int32
+	for i := 0; i < 10; i++ {
+		g.Go(func() error {
+			atomic.AddInt32(&n, 1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Fatalf("ran %d, want 10", n)
+	}
+}
+
+func TestGroupFirstErrorCancels(t *testing.T) {
+	g, ctx := WithContext(context.Background())
+	errFirst := errors.New("first")
+	g.Go(func() error {
+		return errFirst
+	})
+	g.Go(func() error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return errors.New("ctx not cancelled")
+		}
+	})
+	if err := g.Wait(); err != errFirst {
+		t.Fatalf("Wait = %v, want %v", err, errFirst)
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	var g Group
+	g.Go(func() error {
+		panic("boom")
+	})
+	if err := g.Wait(); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("Wait = %v, want panic error", err)
+	}
+}
+
+func TestGroupSetLimit(t *testing.T) {
+	var g Group
+	g.SetLimit(2)
+	var running, peak int32
+	for i := 0; i < 10; i++ {
+		g.Go(func() error {
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if cur <= old || atomic.CompareAndSwapInt32(&peak, old, cur) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if peak > 2 {
+		t.Fatalf("peak concurrency %d, want <= 2", peak)
+	}
+}
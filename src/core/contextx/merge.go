@@ -0,0 +1,24 @@
+package contextx
+
+import "context"
+
+// Merge returns a context that carries primary's values and deadline, and is
+// cancelled when either primary or secondary is done. It starts one goroutine
+// that exits once the returned context is done; call cancel to release it.
+// When cancelled by secondary, Err reports context.Canceled.
+// Merge 返回的 context 保留 primary 的 Value 和 Deadline，primary 或 secondary 任一结束时都会被取消
+// 内部启动一个协程等待 secondary，返回的 context 结束后协程退出，使用完需要调用 cancel
+func Merge(primary, secondary context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(primary)
+	if secondary.Done() == nil {
+		return ctx, cancel
+	}
+	go func() {
+		select {
+		case <-secondary.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
@@ -0,0 +1,65 @@
+package contextx
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func init() {
+
+}
+
+type ctxKey struct{}
+
+func TestMerge(t *testing.T) {
+	primary := context.WithValue(context.Background(), ctxKey{}, "request")
+	shutdown, stop := context.WithCancel(context.Background())
+	ctx, cancel := Merge(primary, shutdown)
+	defer cancel()
+
+	if ctx.Value(ctxKey{}) != "request" {
+		t.Fatal("Merge should keep primary values")
+	}
+	stop()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Merge not cancelled by secondary")
+	}
+
+	primary2, cancelPrimary := context.WithCancel(context.Background())
+	ctx2, cancel2 := Merge(primary2, context.Background())
+	defer cancel2()
+	cancelPrimary()
+	select {
+	case <-ctx2.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Merge not cancelled by primary")
+	}
+}
+
+func TestDetachWithWatchdog(t *testing.T) {
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "v"))
+	exceeded := make(chan struct{}, 1)
+	ctx, _ := DetachWithWatchdog(parent, 20*time.Millisecond, func() { exceeded <- struct{}{} })
+	cancel()
+	if ctx.Err() != nil || ctx.Value(ctxKey{}) != "v" {
+		t.Fatal("watched context should behave like Detach")
+	}
+	select {
+	case <-exceeded:
+	case <-time.After(time.Second):
+		t.Fatal("onExceed not called")
+	}
+	runtime.KeepAlive(ctx)
+
+	_, done := DetachWithWatchdog(context.Background(), 20*time.Millisecond, func() { exceeded <- struct{}{} })
+	done()
+	select {
+	case <-exceeded:
+		t.Fatal("onExceed called after done")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,37 @@
+package contextx
+
+import (
+	"context"
+	"gitlab.com/aiku-open-source/go-help/src/core/logger"
+	"runtime"
+	"time"
+)
+
+// DetachWithWatchdog behaves like Detach, but calls onExceed if done has not
+// been called within maxLifetime. Callers must call done when the background
+// work finishes; a context dropped without calling done may still be reported.
+// A finalizer stops the timer if the context is garbage collected first, but
+// that is only best-effort cleanup. A nil onExceed logs a warning through logger.Log.
+// DetachWithWatchdog 与 Detach 相同，但超过 maxLifetime 仍未调用 done 时会调用 onExceed
+// 后台任务结束时必须调用 done，不再使用 context 但未调用 done 也可能被报告；context 被 GC 回收时会停止计时，但只是尽力而为
+// 用于发现脱离了父 context 后一直没有结束的后台任务，onExceed 为 nil 时通过 logger.Log 打印警告
+func DetachWithWatchdog(ctx context.Context, maxLifetime time.Duration, onExceed func()) (detached context.Context, done func()) {
+	if onExceed == nil {
+		onExceed = func() {
+			if logger.Log != nil {
+				logger.Log.Warnf("detached context still active after %s", maxLifetime)
+			}
+		}
+	}
+	// timer 的回调不能引用 watched，否则 watched 永远不会被回收，finalizer 也就无法停止 timer
+	timer := time.AfterFunc(maxLifetime, onExceed)
+	watched := &watchedContext{detachedContext{ctx}}
+	runtime.SetFinalizer(watched, func(*watchedContext) {
+		timer.Stop()
+	})
+	return watched, func() {
+		timer.Stop()
+	}
+}
+
+type watchedContext struct{ detachedContext }
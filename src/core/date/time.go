@@ -28,24 +28,68 @@ func NextWeekMonday() time.Time {
 	return NextWeekMondayByTime(ToDay())
 }
 func NextWeekMondayByTime(currentTime time.Time) time.Time {
-	// 计算下一周周一的时间
-	daysUntilNextMonday := NextWeekMondayByTimeGetDays(currentTime)
-	return currentTime.Add(time.Duration(daysUntilNextMonday) * 24 * time.Hour)
+	return NextWeekStart(currentTime, time.Monday)
 }
 func NextWeekMondayByTimeGetDays(currentTime time.Time) int {
-	// 获取今天是本周的第几天（0表示周日，1表示周一，依此类推）
-	currentDay := int(currentTime.Weekday())
+	return NextWeekStartGetDays(currentTime, time.Monday)
+}
+
+func WeekMonday() time.Time {
+	return WeekStart(ToDay(), time.Monday)
+}
+
+// WeekStart 返回 t 所在周的第一天（startDay），t 当天就是 startDay 时返回 t 本身，时分秒保持不变
+func WeekStart(t time.Time, startDay time.Weekday) time.Time {
+	// 距离本周第一天已经过去的天数
+	daysSinceStart := (7 + int(t.Weekday()) - int(startDay)) % 7
+	return t.AddDate(0, 0, -daysSinceStart)
+}
+
+// NextWeekStart 返回 t 之后下一周的第一天（startDay），t 当天就是 startDay 时返回 7 天后，时分秒保持不变
+func NextWeekStart(t time.Time, startDay time.Weekday) time.Time {
+	return t.AddDate(0, 0, NextWeekStartGetDays(t, startDay))
+}
 
-	// 计算下一周周一的时间
-	daysUntilNextMonday := (7 - currentDay + 1) % 7 // 计算距离下一周周一还有多少天
-	if daysUntilNextMonday == 0 {
-		daysUntilNextMonday = 7
+// NextWeekStartGetDays 返回 t 距离下一周第一天（startDay）的天数，取值 1~7
+func NextWeekStartGetDays(t time.Time, startDay time.Weekday) int {
+	days := (7 + int(startDay) - int(t.Weekday())) % 7
+	if days == 0 {
+		days = 7
 	}
-	return daysUntilNextMonday
+	return days
 }
 
-func WeekMonday() time.Time {
-	return NextWeekMonday().Add(-7 * 24 * time.Hour)
+// SecondsUntilEndOfDay 返回 loc 时区下距离明天0点的秒数（向上取整），loc 为 nil 时使用本地时区
+func SecondsUntilEndOfDay(loc *time.Location) int {
+	return SecondsUntilEndOfDayByTime(nowIn(loc))
+}
+
+// SecondsUntilEndOfDayByTime 返回 t 距离 t 所在时区下一个0点的秒数，夏令时切换的当天按实际的 23/25 小时计算
+func SecondsUntilEndOfDayByTime(t time.Time) int {
+	nextDay := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	return ceilSeconds(nextDay.Sub(t))
+}
+
+// SecondsUntilEndOfWeek 返回 loc 时区下距离下周一0点的秒数（向上取整），loc 为 nil 时使用本地时区
+func SecondsUntilEndOfWeek(loc *time.Location) int {
+	return SecondsUntilEndOfWeekByTime(nowIn(loc))
+}
+
+// SecondsUntilEndOfWeekByTime 返回 t 距离 t 所在时区下周一0点的秒数
+func SecondsUntilEndOfWeekByTime(t time.Time) int {
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return ceilSeconds(NextWeekStart(today, time.Monday).Sub(t))
+}
+
+func nowIn(loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.Local
+	}
+	return time.Now().In(loc)
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
 }
 
 func Now2Week() string {
@@ -0,0 +1,75 @@
+package date
+
+import (
+	"testing"
+	"time"
+)
+
+func init() {
+
+}
+
+func TestWeekStart(t *testing.T) {
+	// 2024-01-07 是周日，2024-01-08 是周一
+	sunday := time.Date(2024, 1, 7, 10, 0, 0, 0, time.UTC)
+	monday := sunday.AddDate(0, 0, 1)
+	wednesday := sunday.AddDate(0, 0, 3)
+
+	cases := []struct {
+		t         time.Time
+		startDay  time.Weekday
+		weekStart time.Time
+		nextStart time.Time
+	}{
+		{sunday, time.Monday, sunday.AddDate(0, 0, -6), monday},
+		{sunday, time.Sunday, sunday, sunday.AddDate(0, 0, 7)},
+		{monday, time.Monday, monday, monday.AddDate(0, 0, 7)},
+		{monday, time.Sunday, sunday, sunday.AddDate(0, 0, 7)},
+		{wednesday, time.Monday, monday, monday.AddDate(0, 0, 7)},
+		{wednesday, time.Sunday, sunday, sunday.AddDate(0, 0, 7)},
+	}
+	for _, c := range cases {
+		if got := WeekStart(c.t, c.startDay); !got.Equal(c.weekStart) {
+			t.Errorf("WeekStart(%v, %v) = %v, want %v", c.t, c.startDay, got, c.weekStart)
+		}
+		if got := NextWeekStart(c.t, c.startDay); !got.Equal(c.nextStart) {
+			t.Errorf("NextWeekStart(%v, %v) = %v, want %v", c.t, c.startDay, got, c.nextStart)
+		}
+	}
+	if got := NextWeekMondayByTime(monday); !got.Equal(monday.AddDate(0, 0, 7)) {
+		t.Errorf("NextWeekMondayByTime(%v) = %v", monday, got)
+	}
+}
+
+func TestSecondsUntilEnd(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	cases := []struct {
+		t    time.Time
+		day  int
+		week int
+	}{
+		// 普通的一天，周三
+		{time.Date(2024, 1, 10, 23, 0, 0, 0, loc), Hour, Hour + 4*Day},
+		{time.Date(2024, 1, 10, 23, 59, 59, 500, loc), 1, 1 + 4*Day},
+		// 2024-03-10 夏令时开始，当天只有 23 小时
+		{time.Date(2024, 3, 10, 0, 0, 0, 0, loc), 23 * Hour, 23 * Hour},
+		// 2024-11-03 夏令时结束，当天有 25 小时
+		{time.Date(2024, 11, 3, 0, 0, 0, 0, loc), 25 * Hour, 25 * Hour},
+		// 周一0点距离周末是完整的一周
+		{time.Date(2024, 1, 8, 0, 0, 0, 0, loc), Day, Week},
+	}
+	for _, c := range cases {
+		if got := SecondsUntilEndOfDayByTime(c.t); got != c.day {
+			t.Errorf("SecondsUntilEndOfDayByTime(%v) = %d, want %d", c.t, got, c.day)
+		}
+		if got := SecondsUntilEndOfWeekByTime(c.t); got != c.week {
+			t.Errorf("SecondsUntilEndOfWeekByTime(%v) = %d, want %d", c.t, got, c.week)
+		}
+	}
+	if got := SecondsUntilEndOfDay(nil); got <= 0 || got > 25*Hour {
+		t.Errorf("SecondsUntilEndOfDay(nil) = %d", got)
+	}
+}
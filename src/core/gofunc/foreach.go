@@ -0,0 +1,91 @@
+package gofunc
+
+import (
+	"context"
+	"gitlab.com/aiku-open-source/go-help/src/core/conc"
+	"gitlab.com/aiku-open-source/go-help/src/core/hotfix"
+	"strings"
+)
+
+// Errors 多个错误的集合，ForEachAll 返回
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap 返回全部错误；errors.Is/errors.As 从 Go 1.20 起才会展开 Unwrap() []error
+// go.mod 声明的是 go 1.18，更低版本的工具链下需要自己遍历 Errors
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// ForEach 并发对 items 执行 fn，同时最多 concurrency 个（<= 0 表示不限制）
+// 第一个错误（包括 panic）会取消 ctx，尚未开始的 item 不再执行，返回第一个错误
+func ForEach[T any](ctx context.Context, items []T, concurrency int, fn func(ctx context.Context, item T) error) error {
+	g, gCtx := conc.WithContext(ctx)
+	if concurrency > 0 {
+		g.SetLimit(concurrency)
+	}
+	for _, item := range items {
+		if gCtx.Err() != nil {
+			break
+		}
+		item := item
+		g.Go(func() error {
+			if err := gCtx.Err(); err != nil {
+				return err
+			}
+			return fn(gCtx, item)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// ForEachAll 同 ForEach，但出错后不取消，所有 item 都会执行，返回按 items 顺序收集的 Errors，全部成功时返回 nil
+// ctx 结束后尚未开始的 item 不再执行，记为 ctx.Err()
+func ForEachAll[T any](ctx context.Context, items []T, concurrency int, fn func(ctx context.Context, item T) error) error {
+	var (
+		g    conc.Group
+		errs = make([]error, len(items)) // 每个协程只写自己的下标，Wait 之后再读
+	)
+	if concurrency > 0 {
+		g.SetLimit(concurrency)
+	}
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+		i, item := i, item
+		g.Go(func() (err error) {
+			defer func() {
+				errs[i] = err
+			}()
+			defer hotfix.RecoverAsError(&err)
+			// 等待并发名额期间 ctx 可能已经结束
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fn(ctx, item)
+		})
+	}
+	_ = g.Wait()
+	var res Errors
+	for _, err := range errs {
+		if err != nil {
+			res = append(res, err)
+		}
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
@@ -2,6 +2,7 @@ package gofunc
 
 import (
 	"context"
+	"errors"
 	"gitlab.com/aiku-open-source/go-help/src/core/logger"
 	"time"
 )
@@ -33,3 +34,30 @@ func CoroutineWithTimeOut(ctx context.Context, timeout time.Duration, f func(tim
 		f(timeoutCtx)
 	}()
 }
+
+// CoroutineWithTimeOutCallback 同 CoroutineWithTimeOut，f 返回（或 panic）后调用 onDone
+// timedOut 表示 f 返回时 timeoutCtx 是否已经超时，onDone 同样有 panic 恢复
+func CoroutineWithTimeOutCallback(ctx context.Context, timeout time.Duration, f func(timeoutCtx context.Context), onDone func(timedOut bool)) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+
+	go func() {
+		defer cancel()
+		func() {
+			defer recoverLog(timeoutCtx)
+			f(timeoutCtx)
+		}()
+
+		// 在 cancel 之前判断，避免把主动取消误判为超时
+		timedOut := errors.Is(timeoutCtx.Err(), context.DeadlineExceeded)
+		defer recoverLog(timeoutCtx)
+		onDone(timedOut)
+	}()
+}
+
+func recoverLog(ctx context.Context) {
+	if err := recover(); err != nil {
+		if logger.Log != nil {
+			logger.Log.Error(ctx, "GoFunc err:", err)
+		}
+	}
+}
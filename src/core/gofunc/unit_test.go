@@ -0,0 +1,140 @@
+package gofunc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func init() {
+
+}
+
+func TestCoroutineWithTimeOutCallback(t *testing.T) {
+	cases := []struct {
+		name     string
+		f        func(ctx context.Context)
+		timedOut bool
+	}{
+		{"finish", func(ctx context.Context) {}, false},
+		{"timeout", func(ctx context.Context) { <-ctx.Done() }, true},
+		{"panic", func(ctx context.Context) { panic("boom") }, false},
+	}
+	for _, c := range cases {
+		done := make(chan bool, 1)
+		CoroutineWithTimeOutCallback(context.Background(), 20*time.Millisecond, c.f, func(timedOut bool) {
+			done <- timedOut
+		})
+		select {
+		case timedOut := <-done:
+			if timedOut != c.timedOut {
+				t.Errorf("%s: timedOut = %v, want %v", c.name, timedOut, c.timedOut)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%s: onDone not called", c.name)
+		}
+	}
+}
+
+func TestForEach(t *testing.T) {
+	items := make([]int, 20)
+	for i := range items {
+		items[i] = i
+	}
+	var running, peak, sum int32
+	err := ForEach(context.Background(), items, 3, func(ctx context.Context, item int) error {
+		cur := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if cur <= old || atomic.CompareAndSwapInt32(&peak, old, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&sum, int32(item))
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+	if err != nil || sum != 190 || peak > 3 {
+		t.Fatalf("ForEach = %v, sum %d, peak %d", err, sum, peak)
+	}
+}
+
+func TestForEachFailFast(t *testing.T) {
+	errBad := errors.New("bad")
+	var ran int32
+	err := ForEach(context.Background(), []int{1, 2, 3, 4, 5, 6, 7, 8}, 1, func(ctx context.Context, item int) error {
+		atomic.AddInt32(&ran, 1)
+		if item == 2 {
+			return errBad
+		}
+		return nil
+	})
+	if err != errBad {
+		t.Fatalf("ForEach = %v, want %v", err, errBad)
+	}
+	if ran > 3 {
+		t.Fatalf("ran %d items after first error", ran)
+	}
+}
+
+func TestForEachAll(t *testing.T) {
+	err := ForEachAll(context.Background(), []int{1, 2, 3, 4}, 2, func(ctx context.Context, item int) error {
+		switch item {
+		case 2:
+			return errors.New("two")
+		case 4:
+			panic("four")
+		}
+		return nil
+	})
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("ForEachAll = %v", err)
+	}
+	if errs[0].Error() != "two" || !strings.Contains(errs[1].Error(), "four") {
+		t.Fatalf("ForEachAll errors = %v", errs)
+	}
+	if err := ForEachAll(context.Background(), []int{1}, 0, func(ctx context.Context, item int) error { return nil }); err != nil {
+		t.Fatalf("ForEachAll = %v, want nil", err)
+	}
+}
+
+func TestForEachAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var ran int32
+	err := ForEachAll(ctx, make([]int, 50), 2, func(ctx context.Context, item int) error {
+		atomic.AddInt32(&ran, 1)
+		return nil
+	})
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 50 || errs[0] != context.Canceled {
+		t.Fatalf("ForEachAll = %v", err)
+	}
+	if ran != 0 {
+		t.Fatalf("ran %d items with cancelled ctx", ran)
+	}
+
+	// 运行中取消，之后未开始的 item 记为 ctx.Err()
+	ctx, cancel = context.WithCancel(context.Background())
+	ran = 0
+	err = ForEachAll(ctx, []int{1, 2, 3, 4, 5}, 1, func(ctx context.Context, item int) error {
+		atomic.AddInt32(&ran, 1)
+		if item == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.As(err, &errs) || len(errs) != 3 || ran != 2 {
+		t.Fatalf("ForEachAll = %v, ran %d", err, ran)
+	}
+	for _, e := range errs {
+		if e != context.Canceled {
+			t.Fatalf("ForEachAll errors = %v", errs)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package hotfix
 
 import (
+	"fmt"
 	"gitlab.com/aiku-open-source/go-help/src/core/logger"
 	"runtime/debug"
 )
@@ -12,3 +13,13 @@ func RecoverError() {
 		}
 	}
 }
+
+// RecoverAsError 同 RecoverError，并把 panic 转成 error 写入 errp，需要直接 defer 调用
+func RecoverAsError(errp *error) {
+	if err := recover(); err != nil {
+		if logger.Log != nil {
+			logger.Log.Errorf("err:%+v\nStack:%s", err, string(debug.Stack()))
+		}
+		*errp = fmt.Errorf("panic: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package mathx
+
+// Ordered 支持 < 比较的类型
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+func Min[T Ordered](a, b T) T {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func Max[T Ordered](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func MinInt64(a, b int64) int64 {
+	return Min(a, b)
+}
+
+// Clamp 把 v 限制在 [lo, hi] 内，lo > hi 时返回 lo
+func Clamp[T Ordered](v, lo, hi T) T {
+	return Max(lo, Min(v, hi))
+}
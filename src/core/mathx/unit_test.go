@@ -0,0 +1,35 @@
+package mathx
+
+import (
+	"testing"
+)
+
+func init() {
+
+}
+
+func TestMinMax(t *testing.T) {
+	if Min(3, 5) != 3 || Max(3, 5) != 5 {
+		t.Fatal("Min/Max int")
+	}
+	if Min("b", "a") != "a" || Max(1.5, -2.0) != 1.5 {
+		t.Fatal("Min/Max string/float")
+	}
+	if MinInt64(-1, 1) != -1 {
+		t.Fatal("MinInt64")
+	}
+}
+
+func TestClamp(t *testing.T) {
+	cases := []struct{ v, lo, hi, want int64 }{
+		{5, 0, 10, 5},
+		{-3, 0, 10, 0},
+		{11, 0, 10, 10},
+		{5, 10, 0, 10},
+	}
+	for _, c := range cases {
+		if got := Clamp(c.v, c.lo, c.hi); got != c.want {
+			t.Errorf("Clamp(%d, %d, %d) = %d, want %d", c.v, c.lo, c.hi, got, c.want)
+		}
+	}
+}
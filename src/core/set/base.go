@@ -0,0 +1,78 @@
+package set
+
+import "sort"
+
+// Set 基于 map 的泛型集合，零值不可用，使用 New 创建
+type Set[T comparable] map[T]struct{}
+
+func New[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	s.Add(items...)
+	return s
+}
+
+func (s Set[T]) Add(items ...T) {
+	for _, v := range items {
+		s[v] = struct{}{}
+	}
+}
+
+func (s Set[T]) Remove(items ...T) {
+	for _, v := range items {
+		delete(s, v)
+	}
+}
+
+func (s Set[T]) Contains(v T) bool {
+	_, ok := s[v]
+	return ok
+}
+
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Union 返回并集，不修改 s 和 other
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	res := make(Set[T], len(s)+len(other))
+	for v := range s {
+		res[v] = struct{}{}
+	}
+	for v := range other {
+		res[v] = struct{}{}
+	}
+	return res
+}
+
+// Intersect 返回交集，不修改 s 和 other
+func (s Set[T]) Intersect(other Set[T]) Set[T] {
+	small, large := s, other
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	res := make(Set[T], len(small))
+	for v := range small {
+		if large.Contains(v) {
+			res[v] = struct{}{}
+		}
+	}
+	return res
+}
+
+// ToSlice 返回所有元素，顺序不固定
+func (s Set[T]) ToSlice() []T {
+	res := make([]T, 0, len(s))
+	for v := range s {
+		res = append(res, v)
+	}
+	return res
+}
+
+// ToSortedSlice 返回按 less 排序后的所有元素，用于需要稳定输出的场景
+func (s Set[T]) ToSortedSlice(less func(a, b T) bool) []T {
+	res := s.ToSlice()
+	sort.Slice(res, func(i, j int) bool {
+		return less(res[i], res[j])
+	})
+	return res
+}
@@ -0,0 +1,41 @@
+package set
+
+import (
+	"fmt"
+	"testing"
+)
+
+func init() {
+
+}
+
+func intLess(a, b int) bool { return a < b }
+
+func TestSet(t *testing.T) {
+	s := New(3, 1, 2, 3)
+	if s.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", s.Len())
+	}
+	if !s.Contains(2) || s.Contains(4) {
+		t.Fatal("Contains mismatch")
+	}
+	s.Add(4)
+	s.Remove(1, 5)
+	if got := fmt.Sprint(s.ToSortedSlice(intLess)); got != "[2 3 4]" {
+		t.Fatalf("after Add/Remove = %s", got)
+	}
+}
+
+func TestUnionIntersect(t *testing.T) {
+	a := New(1, 2, 3)
+	b := New(2, 3, 4, 5)
+	if got := fmt.Sprint(a.Union(b).ToSortedSlice(intLess)); got != "[1 2 3 4 5]" {
+		t.Fatalf("Union = %s", got)
+	}
+	if got := fmt.Sprint(a.Intersect(b).ToSortedSlice(intLess)); got != "[2 3]" {
+		t.Fatalf("Intersect = %s", got)
+	}
+	if a.Len() != 3 || b.Len() != 4 {
+		t.Fatal("Union/Intersect modified operands")
+	}
+}
@@ -0,0 +1,53 @@
+package slices
+
+// Map 对每个元素执行 f，返回结果组成的新切片
+func Map[T, U any](list []T, f func(T) U) []U {
+	if list == nil {
+		return nil
+	}
+	res := make([]U, 0, len(list))
+	for _, v := range list {
+		res = append(res, f(v))
+	}
+	return res
+}
+
+// Filter 返回 f 为 true 的元素组成的新切片，不修改原切片
+func Filter[T any](list []T, f func(T) bool) []T {
+	if list == nil {
+		return nil
+	}
+	res := make([]T, 0, len(list))
+	for _, v := range list {
+		if f(v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Reduce 从 initial 开始依次用 f 累积每个元素
+func Reduce[T, U any](list []T, initial U, f func(U, T) U) U {
+	acc := initial
+	for _, v := range list {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// Unique 去重，保留每个元素第一次出现的位置
+func Unique[T comparable](list []T) []T {
+	if list == nil {
+		return nil
+	}
+	seen := make(map[T]struct{}, len(list))
+	res := make([]T, 0, len(list))
+	for _, v := range list {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		res = append(res, v)
+	}
+	return res
+}
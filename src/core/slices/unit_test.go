@@ -0,0 +1,55 @@
+package slices
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func init() {
+
+}
+
+func TestMap(t *testing.T) {
+	res := Map([]int64{1, 22, 333}, func(v int64) string {
+		return strconv.FormatInt(v, 10)
+	})
+	if fmt.Sprintf("%q", res) != `["1" "22" "333"]` {
+		t.Fatalf("Map = %q", res)
+	}
+	if Map[int, int](nil, nil) != nil {
+		t.Fatal("Map(nil) should be nil")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	list := []int{1, 2, 3, 4, 5, 6}
+	res := Filter(list, func(v int) bool { return v%2 == 0 })
+	if fmt.Sprint(res) != "[2 4 6]" {
+		t.Fatalf("Filter = %v", res)
+	}
+	if fmt.Sprint(list) != "[1 2 3 4 5 6]" {
+		t.Fatalf("Filter modified input: %v", list)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	sum := Reduce([]int{1, 2, 3, 4}, 0, func(acc, v int) int { return acc + v })
+	if sum != 10 {
+		t.Fatalf("Reduce sum = %d", sum)
+	}
+	joined := Reduce([]int{1, 2, 3}, "", func(acc string, v int) string { return acc + strconv.Itoa(v) })
+	if joined != "123" {
+		t.Fatalf("Reduce join = %q", joined)
+	}
+	if Reduce(nil, 7, func(acc, v int) int { return acc + v }) != 7 {
+		t.Fatal("Reduce(nil) should return initial")
+	}
+}
+
+func TestUnique(t *testing.T) {
+	res := Unique([]string{"b", "a", "b", "c", "a"})
+	if fmt.Sprint(res) != "[b a c]" {
+		t.Fatalf("Unique = %v", res)
+	}
+}
@@ -1,42 +1,222 @@
 package token_bucket
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-type TokenBucket struct {
-	c   chan struct{}
-	max int
-}
+type (
+	TokenBucket struct {
+		// 累计放入/取出的令牌数，放在结构体开头保证 32 位平台上 64 位原子操作的对齐
+		pushed uint64
+		popped uint64
+
+		c         chan struct{}
+		max       int
+		policy    int32
+		done      chan struct{}
+		closeOnce sync.Once
+	}
+
+	// PushPolicy 桶满时 Push 的处理方式
+	PushPolicy int32
+)
+
+const (
+	// PushDropExcess 桶满时丢弃放不下的令牌，默认策略
+	PushDropExcess PushPolicy = iota
+	// PushBlock 桶满时阻塞直到有空位
+	PushBlock
+	// PushError 桶满时放入能放下的部分，剩余的返回 ErrBucketFull
+	PushError
+)
+
+var (
+	ErrBucketFull = errors.New("token bucket is full")
+	ErrClosed     = errors.New("token bucket is closed")
+)
 
 func NewTokenBucket(max int) *TokenBucket {
 	result := new(TokenBucket)
 	result.c = make(chan struct{}, max)
 	result.max = max
+	result.done = make(chan struct{})
 	return result
 }
 
+// SetPushPolicy 设置桶满时 Push 的处理方式
+func (t *TokenBucket) SetPushPolicy(policy PushPolicy) {
+	atomic.StoreInt32(&t.policy, int32(policy))
+}
+
+// TickerPush 每 intervalSecond 秒放入 num 个令牌，桶满时丢弃多余的令牌，不受 PushPolicy 影响，避免阻塞定时补充
+// Close 之后返回
 func (t *TokenBucket) TickerPush(intervalSecond, num int) {
-	t.Push(num)
+	t.tryPush(num)
+	ticker := time.NewTicker(time.Second * time.Duration(intervalSecond))
+	defer ticker.Stop()
 	for {
 		select {
-		case <-time.NewTicker(time.Second * time.Duration(intervalSecond)).C:
-			if len(t.c) <= t.max-num {
-				t.Push(num)
+		case <-ticker.C:
+			t.tryPush(num)
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// Push 放入 num 个令牌，返回未能放入的数量，桶满时的行为由 PushPolicy 决定
+// Close 之后返回 ErrClosed
+func (t *TokenBucket) Push(num int) (dropped int, err error) {
+	if num <= 0 {
+		return 0, nil
+	}
+	if t.isClosed() {
+		return num, ErrClosed
+	}
+	switch PushPolicy(atomic.LoadInt32(&t.policy)) {
+	case PushBlock:
+		for i := 0; i < num; i++ {
+			select {
+			case t.c <- struct{}{}:
+				atomic.AddUint64(&t.pushed, 1)
+			case <-t.done:
+				return num - i, ErrClosed
 			}
 		}
+		return 0, nil
+	case PushError:
+		if dropped = num - t.tryPush(num); dropped > 0 {
+			return dropped, fmt.Errorf("%w: %d tokens not pushed", ErrBucketFull, dropped)
+		}
+		return 0, nil
+	default:
+		return num - t.tryPush(num), nil
 	}
 }
-func (t *TokenBucket) Push(num int) {
+
+// tryPush 不阻塞地放入最多 num 个令牌，返回实际放入的数量
+func (t *TokenBucket) tryPush(num int) int {
+	n := t.fill(num)
+	atomic.AddUint64(&t.pushed, uint64(n))
+	return n
+}
+
+// fill 不阻塞地向桶里放入最多 num 个令牌，不计数
+func (t *TokenBucket) fill(num int) int {
+	if num <= 0 {
+		return 0
+	}
 	for i := 0; i < num; i++ {
-		t.c <- struct{}{}
+		select {
+		case t.c <- struct{}{}:
+		default:
+			return i
+		}
 	}
+	return num
 }
+
+// Pop 取出 num 个令牌，令牌不足时阻塞，Close 之后立即返回
 func (t *TokenBucket) Pop(num int) {
 	for i := 0; i < num; i++ {
-		<-t.c
+		select {
+		case <-t.c:
+			atomic.AddUint64(&t.popped, 1)
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// TryPop 不阻塞地取出 num 个令牌，令牌不足或已 Close 时不取出并返回 false
+func (t *TokenBucket) TryPop(num int) bool {
+	if t.isClosed() {
+		return false
+	}
+	for i := 0; i < num; i++ {
+		select {
+		case <-t.c:
+			atomic.AddUint64(&t.popped, 1)
+		default:
+			t.refund(i)
+			return false
+		}
+	}
+	return true
+}
+
+// PopResult 在 ctx 结束前取出 num 个令牌，返回等待时长和取出后桶内剩余的令牌数
+// ctx 结束时已取出的令牌会归还，并返回 ctx.Err()
+func (t *TokenBucket) PopResult(ctx context.Context, num int) (waited time.Duration, remaining int, err error) {
+	start := time.Now()
+	got, err := t.pop(ctx, num)
+	if err != nil {
+		t.refund(got)
+	}
+	return time.Since(start), len(t.c), err
+}
+
+// Reserve 在 ctx 结束前取出 num 个令牌，ok 为 false 表示超时未取够（已取出的会归还）
+// release 用于调用方放弃使用时归还令牌，多次调用只会归还一次
+func (t *TokenBucket) Reserve(ctx context.Context, num int) (release func(), ok bool) {
+	got, err := t.pop(ctx, num)
+	if err != nil {
+		t.refund(got)
+		return func() {}, false
+	}
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			t.refund(num)
+		})
+	}
+	return release, true
+}
+
+// pop 取出 num 个令牌，ctx 结束或 Close 时返回已取出的数量
+func (t *TokenBucket) pop(ctx context.Context, num int) (int, error) {
+	for i := 0; i < num; i++ {
+		select {
+		case <-ctx.Done():
+			return i, ctx.Err()
+		case <-t.done:
+			return i, ErrClosed
+		case <-t.c:
+			atomic.AddUint64(&t.popped, 1)
+		}
+	}
+	return num, nil
+}
+
+// refund 归还 pop 取出但未使用的令牌，不计入累计放入/取出数，桶已被补满时丢弃
+func (t *TokenBucket) refund(num int) {
+	if n := t.fill(num); n > 0 {
+		atomic.AddUint64(&t.popped, ^uint64(n-1))
 	}
 }
+
+// Stats 返回当前可用令牌数、容量，以及累计取出和放入的令牌数
+func (t *TokenBucket) Stats() (available, capacity int, totalPopped, totalPushed uint64) {
+	return len(t.c), t.max, atomic.LoadUint64(&t.popped), atomic.LoadUint64(&t.pushed)
+}
+
+// Close 关闭令牌桶，可以重复调用；之后 Push 返回 ErrClosed，阻塞中的 Pop 立即返回
 func (t *TokenBucket) Close() {
-	close(t.c)
+	t.closeOnce.Do(func() {
+		close(t.done)
+	})
+}
+
+func (t *TokenBucket) isClosed() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
 }
@@ -0,0 +1,53 @@
+package token_bucket
+
+import (
+	"sync"
+	"time"
+)
+
+// SlidingWindowCounter 进程内的滑动窗口计数器，任意 window 时长内最多允许 limit 次
+// 用环形数组记录最近 limit 次通过的时间，Allow 不产生内存分配
+type SlidingWindowCounter struct {
+	mu     sync.Mutex
+	ring   []int64 // 通过时间相对 start 的偏移，单位纳秒
+	head   int     // 最早一次通过在 ring 中的下标
+	count  int
+	window int64
+	start  time.Time // 保留单调时钟读数，系统时间被调整时窗口计算不受影响
+	now    func() time.Time
+}
+
+func NewSlidingWindowCounter(limit int, window time.Duration) *SlidingWindowCounter {
+	if limit < 0 {
+		limit = 0
+	}
+	return &SlidingWindowCounter{
+		ring:   make([]int64, limit),
+		window: int64(window),
+		start:  time.Now(),
+		now:    time.Now,
+	}
+}
+
+// Allow 当前窗口内未达到上限时记录一次并返回 true
+func (s *SlidingWindowCounter) Allow() bool {
+	now := int64(s.now().Sub(s.start))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	size := len(s.ring)
+	if size == 0 {
+		return false
+	}
+	if s.count < size {
+		s.ring[(s.head+s.count)%size] = now
+		s.count++
+		return true
+	}
+	// 已满时最早的一次滑出窗口才能通过，覆盖最早的记录
+	if now-s.ring[s.head] < s.window {
+		return false
+	}
+	s.ring[s.head] = now
+	s.head = (s.head + 1) % size
+	return true
+}
@@ -0,0 +1,124 @@
+package token_bucket
+
+import (
+	"context"
+	"gitlab.com/aiku-open-source/go-help/src/core/mathx"
+	"io"
+)
+
+type (
+	throttle struct {
+		ctx           context.Context
+		bucket        *TokenBucket
+		bytesPerToken int
+		credit        int // 已付过令牌、尚未读写的字节数，留给下次使用；Reader 欠款时为负
+	}
+
+	throttledReader struct {
+		throttle
+		r io.Reader
+	}
+
+	throttledWriter struct {
+		throttle
+		w io.Writer
+	}
+)
+
+// NewThrottledReader 返回按读取字节数消耗令牌的 io.Reader，每 bytesPerToken 字节消耗一个令牌
+func NewThrottledReader(r io.Reader, bucket *TokenBucket, bytesPerToken int) io.Reader {
+	return NewThrottledReaderContext(context.Background(), r, bucket, bytesPerToken)
+}
+
+// NewThrottledReaderContext 同 NewThrottledReader，ctx 结束时等待令牌的 Read 返回 ctx.Err()
+func NewThrottledReaderContext(ctx context.Context, r io.Reader, bucket *TokenBucket, bytesPerToken int) io.Reader {
+	return &throttledReader{
+		throttle: newThrottle(ctx, bucket, bytesPerToken),
+		r:        r,
+	}
+}
+
+// NewThrottledWriter 返回按写入字节数消耗令牌的 io.Writer，每 bytesPerToken 字节消耗一个令牌
+func NewThrottledWriter(w io.Writer, bucket *TokenBucket, bytesPerToken int) io.Writer {
+	return NewThrottledWriterContext(context.Background(), w, bucket, bytesPerToken)
+}
+
+// NewThrottledWriterContext 同 NewThrottledWriter，ctx 结束时等待令牌的 Write 返回 ctx.Err()
+func NewThrottledWriterContext(ctx context.Context, w io.Writer, bucket *TokenBucket, bytesPerToken int) io.Writer {
+	return &throttledWriter{
+		throttle: newThrottle(ctx, bucket, bytesPerToken),
+		w:        w,
+	}
+}
+
+func newThrottle(ctx context.Context, bucket *TokenBucket, bytesPerToken int) throttle {
+	if bytesPerToken <= 0 {
+		bytesPerToken = 1
+	}
+	return throttle{
+		ctx:           ctx,
+		bucket:        bucket,
+		bytesPerToken: bytesPerToken,
+	}
+}
+
+// chunk 单次读写最多一桶令牌对应的字节数，避免一次等待过久
+func (t *throttle) chunk(n int) int {
+	if limit := t.bucket.max * t.bytesPerToken; limit > 0 {
+		return mathx.Min(n, limit)
+	}
+	return n
+}
+
+// pay 付令牌直到 credit >= n；一次取不够时，refund 为 true 归还已取出的令牌，否则计入 credit
+func (t *throttle) pay(n int, refund bool) error {
+	need := n - t.credit
+	if need <= 0 {
+		return nil
+	}
+	num := (need + t.bytesPerToken - 1) / t.bytesPerToken
+	got, err := t.bucket.pop(t.ctx, num)
+	if err != nil {
+		if refund {
+			t.bucket.refund(got)
+		} else {
+			t.credit += got * t.bytesPerToken
+		}
+		return err
+	}
+	t.credit += num * t.bytesPerToken
+	return nil
+}
+
+// Read 先读后付，读到的字节已交给调用方，所以最多会先于令牌多读一个 chunk
+// 付不够时 credit 为负（欠款），下次 Read 前先还清；已取出的令牌抵扣已读的字节，不归还
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if err := t.pay(0, false); err != nil {
+		return 0, err
+	}
+	n, err := t.r.Read(p[:t.chunk(len(p))])
+	t.credit -= n
+	if payErr := t.pay(0, false); payErr != nil {
+		return n, payErr
+	}
+	return n, err
+}
+
+// Write 先付后写，付不够时归还已取出的令牌，这部分字节不写入
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		size := t.chunk(len(p))
+		if err := t.pay(size, true); err != nil {
+			return written, err
+		}
+		n, err := t.w.Write(p[:size])
+		t.credit -= n
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[size:]
+	}
+	return written, nil
+}
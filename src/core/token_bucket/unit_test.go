@@ -1,8 +1,13 @@
 package token_bucket
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 )
 
 func init() {
@@ -21,3 +26,247 @@ func TestDo(t *testing.T) {
 	tokenBucket.Close()
 
 }
+
+func TestReserve(t *testing.T) {
+	tokenBucket := NewTokenBucket(3)
+	tokenBucket.Push(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, ok := tokenBucket.Reserve(ctx, 3); ok {
+		t.Fatal("reserve 3 of 2 tokens should fail")
+	}
+	if n := len(tokenBucket.c); n != 2 {
+		t.Fatalf("tokens after failed reserve = %d, want 2", n)
+	}
+
+	release, ok := tokenBucket.Reserve(context.Background(), 2)
+	if !ok {
+		t.Fatal("reserve 2 of 2 tokens should succeed")
+	}
+	release()
+	release()
+	if n := len(tokenBucket.c); n != 2 {
+		t.Fatalf("tokens after double release = %d, want 2", n)
+	}
+}
+
+func TestThrottledReader(t *testing.T) {
+	tokenBucket := NewTokenBucket(10)
+	tokenBucket.Push(10)
+
+	// 30 字节按每令牌 4 字节需要 8 个令牌
+	b, err := io.ReadAll(NewThrottledReader(strings.NewReader(strings.Repeat("a", 30)), tokenBucket, 4))
+	if err != nil || len(b) != 30 {
+		t.Fatalf("ReadAll = %d, %v; want 30, nil", len(b), err)
+	}
+	if available, _, _, _ := tokenBucket.Stats(); available > 2 {
+		t.Fatalf("tokens left = %d, want <= 2", available)
+	}
+
+	// 先读后付：读完一个 chunk（40 字节）只付得起 5 个令牌，已取出的令牌抵扣已读的字节
+	tokenBucket = NewTokenBucket(10)
+	tokenBucket.Push(5)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	r := NewThrottledReaderContext(ctx, strings.NewReader(strings.Repeat("a", 100)), tokenBucket, 4)
+	b, err = io.ReadAll(r)
+	if err != context.DeadlineExceeded || len(b) != 40 {
+		t.Fatalf("ReadAll = %d, %v; want 40, %v", len(b), err, context.DeadlineExceeded)
+	}
+	if available, _, popped, _ := tokenBucket.Stats(); available != 0 || popped != 5 {
+		t.Fatalf("Stats = %d, %d; want 0, 5", available, popped)
+	}
+	// 欠款还清之前不再读取
+	if n, err := r.Read(make([]byte, 10)); n != 0 || err != context.DeadlineExceeded {
+		t.Fatalf("Read = %d, %v; want 0, %v", n, err, context.DeadlineExceeded)
+	}
+}
+
+func TestThrottledWriter(t *testing.T) {
+	tokenBucket := NewTokenBucket(3)
+	tokenBucket.Push(3)
+	var buf strings.Builder
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	w := NewThrottledWriterContext(ctx, &buf, tokenBucket, 1)
+
+	// 一桶最多 3 个令牌，先写 3 字节，剩余 2 字节等不到令牌
+	n, err := w.Write([]byte("hello"))
+	if n != 3 || err != context.DeadlineExceeded || buf.String() != "hel" {
+		t.Fatalf("Write = %d, %v, %q; want 3, %v, %q", n, err, buf.String(), context.DeadlineExceeded, "hel")
+	}
+	if available, _, popped, _ := tokenBucket.Stats(); available != 0 || popped != 3 {
+		t.Fatalf("Stats = %d, %d; want 0, 3", available, popped)
+	}
+
+	tokenBucket.Push(3)
+	w = NewThrottledWriter(&buf, tokenBucket, 2)
+	if n, err := w.Write([]byte("world")); n != 5 || err != nil {
+		t.Fatalf("Write = %d, %v; want 5, nil", n, err)
+	}
+	if available, _, popped, _ := tokenBucket.Stats(); available != 0 || popped != 6 || buf.String() != "helworld" {
+		t.Fatalf("Stats = %d, %d, %q; want 0, 6, %q", available, popped, buf.String(), "helworld")
+	}
+}
+
+func TestSlidingWindowCounter(t *testing.T) {
+	now := time.Unix(0, 0)
+	counter := NewSlidingWindowCounter(3, time.Second)
+	counter.start = now
+	counter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if !counter.Allow() {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+		now = now.Add(300 * time.Millisecond)
+	}
+	// 0ms/300ms/600ms 都还在窗口内
+	if counter.Allow() {
+		t.Fatal("4th request within window should be denied")
+	}
+	now = time.Unix(0, 0).Add(time.Second)
+	if !counter.Allow() {
+		t.Fatal("request after first one leaves window should be allowed")
+	}
+	if counter.Allow() {
+		t.Fatal("window is full again")
+	}
+}
+
+func BenchmarkSlidingWindowCounter(b *testing.B) {
+	counter := NewSlidingWindowCounter(100, time.Millisecond)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		counter.Allow()
+	}
+}
+
+func TestPopResult(t *testing.T) {
+	tokenBucket := NewTokenBucket(5)
+	tokenBucket.Push(3)
+
+	_, remaining, err := tokenBucket.PopResult(context.Background(), 2)
+	if err != nil || remaining != 1 {
+		t.Fatalf("PopResult = %d, %v; want 1, nil", remaining, err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		tokenBucket.Push(1)
+	}()
+	waited, remaining, err := tokenBucket.PopResult(context.Background(), 2)
+	if err != nil || remaining != 0 || waited < 20*time.Millisecond {
+		t.Fatalf("PopResult = %v, %d, %v; want >=20ms, 0, nil", waited, remaining, err)
+	}
+
+	tokenBucket.Push(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, remaining, err = tokenBucket.PopResult(ctx, 2)
+	if err != context.DeadlineExceeded || remaining != 1 {
+		t.Fatalf("PopResult = %d, %v; want 1, %v", remaining, err, context.DeadlineExceeded)
+	}
+}
+
+func TestStats(t *testing.T) {
+	tokenBucket := NewTokenBucket(10)
+	tokenBucket.Push(6)
+	tokenBucket.Pop(2)
+	if _, _, err := tokenBucket.PopResult(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	// 归还的令牌不计入累计数
+	release, _ := tokenBucket.Reserve(context.Background(), 2)
+	release()
+	available, capacity, popped, pushed := tokenBucket.Stats()
+	if available != 3 || capacity != 10 || popped != 3 || pushed != 6 {
+		t.Fatalf("Stats = %d, %d, %d, %d; want 3, 10, 3, 6", available, capacity, popped, pushed)
+	}
+}
+
+func TestPushPolicy(t *testing.T) {
+	tokenBucket := NewTokenBucket(3)
+	if dropped, err := tokenBucket.Push(5); dropped != 2 || err != nil {
+		t.Fatalf("DropExcess Push = %d, %v; want 2, nil", dropped, err)
+	}
+
+	tokenBucket.SetPushPolicy(PushError)
+	tokenBucket.Pop(1)
+	if dropped, err := tokenBucket.Push(3); dropped != 2 || !errors.Is(err, ErrBucketFull) {
+		t.Fatalf("Error Push = %d, %v; want 2, %v", dropped, err, ErrBucketFull)
+	}
+
+	tokenBucket.SetPushPolicy(PushBlock)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		tokenBucket.Pop(1)
+	}()
+	if dropped, err := tokenBucket.Push(1); dropped != 0 || err != nil {
+		t.Fatalf("Block Push = %d, %v; want 0, nil", dropped, err)
+	}
+	if available, _, _, pushed := tokenBucket.Stats(); available != 3 || pushed != 5 {
+		t.Fatalf("Stats = %d, %d; want 3, 5", available, pushed)
+	}
+}
+
+func TestTryPop(t *testing.T) {
+	tokenBucket := NewTokenBucket(5)
+	tokenBucket.Push(2)
+	if tokenBucket.TryPop(3) {
+		t.Fatal("TryPop 3 of 2 tokens should fail")
+	}
+	if !tokenBucket.TryPop(2) {
+		t.Fatal("TryPop 2 of 2 tokens should succeed")
+	}
+	if available, _, popped, _ := tokenBucket.Stats(); available != 0 || popped != 2 {
+		t.Fatalf("Stats = %d, %d; want 0, 2", available, popped)
+	}
+}
+
+func TestClose(t *testing.T) {
+	tokenBucket := NewTokenBucket(5)
+	go tokenBucket.TickerPush(1, 1)
+
+	popped := make(chan struct{})
+	go func() {
+		tokenBucket.Pop(10)
+		close(popped)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	tokenBucket.Close()
+	tokenBucket.Close()
+	select {
+	case <-popped:
+	case <-time.After(time.Second):
+		t.Fatal("Pop still blocked after Close")
+	}
+	if _, err := tokenBucket.Push(1); err != ErrClosed {
+		t.Fatalf("Push after Close = %v, want %v", err, ErrClosed)
+	}
+	if tokenBucket.TryPop(1) {
+		t.Fatal("TryPop after Close should fail")
+	}
+	if _, _, err := tokenBucket.PopResult(context.Background(), 1); err != ErrClosed {
+		t.Fatalf("PopResult after Close = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestPushNonPositive(t *testing.T) {
+	tokenBucket := NewTokenBucket(3)
+	tokenBucket.Push(1)
+	for _, policy := range []PushPolicy{PushDropExcess, PushBlock, PushError} {
+		tokenBucket.SetPushPolicy(policy)
+		for _, num := range []int{0, -2} {
+			if dropped, err := tokenBucket.Push(num); dropped != 0 || err != nil {
+				t.Fatalf("policy %d Push(%d) = %d, %v; want 0, nil", policy, num, dropped, err)
+			}
+		}
+	}
+	tokenBucket.refund(-2)
+	if available, _, popped, pushed := tokenBucket.Stats(); available != 1 || popped != 0 || pushed != 1 {
+		t.Fatalf("Stats = %d, %d, %d; want 1, 0, 1", available, popped, pushed)
+	}
+}